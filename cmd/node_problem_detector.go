@@ -17,32 +17,38 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
 
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/node-problem-detector/cmd/options"
 	"k8s.io/node-problem-detector/pkg/custompluginmonitor"
-	"k8s.io/node-problem-detector/pkg/problemclient"
+	"k8s.io/node-problem-detector/pkg/exporters"
+	"k8s.io/node-problem-detector/pkg/metrics"
 	"k8s.io/node-problem-detector/pkg/problemdetector"
+	"k8s.io/node-problem-detector/pkg/readiness"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor"
 	"k8s.io/node-problem-detector/pkg/types"
 	"k8s.io/node-problem-detector/pkg/version"
 )
 
-func startHTTPServer(p problemdetector.ProblemDetector, npdo *options.NodeProblemDetectorOptions) {
-	// Add healthz http request handler. Always return ok now, add more health check
-	// logic in the future.
-	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("ok"))
-	})
+func startHTTPServer(p problemdetector.ProblemDetector, readinessChecker *readiness.Checker, npdo *options.NodeProblemDetectorOptions) {
+	// /livez reflects process liveness: if the server can answer, the process
+	// is alive. /readyz only returns ok once kube-apiserver is reachable (when
+	// a sink that depends on it is configured) and every monitor has started;
+	// a monitor need not have produced a status yet. /healthz is kept as an
+	// alias of /livez for backward compatibility with existing probes.
+	http.HandleFunc("/livez", readinessChecker.LivezHandler())
+	http.HandleFunc("/readyz", readinessChecker.ReadyzHandler())
+	http.HandleFunc("/healthz", readinessChecker.LivezHandler())
 	// Add the http handlers in problem detector.
 	p.RegisterHTTPHandlers()
 
@@ -55,6 +61,22 @@ func startHTTPServer(p problemdetector.ProblemDetector, npdo *options.NodeProble
 	}()
 }
 
+// startPrometheusServer starts a dedicated http server serving only the
+// Prometheus /metrics endpoint, used when the operator wants metrics
+// scraped on a listener separate from the main health/pprof server.
+func startPrometheusServer(npdo *options.NodeProblemDetectorOptions) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	addr := net.JoinHostPort(npdo.PrometheusAddress, strconv.Itoa(npdo.PrometheusPort))
+	go func() {
+		err := http.ListenAndServe(addr, mux)
+		if err != nil {
+			glog.Fatalf("Failed to start prometheus server: %v", err)
+		}
+	}()
+}
+
 func main() {
 	npdo := options.NewNodeProblemDetectorOptions()
 	npdo.AddFlags(pflag.CommandLine)
@@ -77,7 +99,13 @@ func main() {
 			glog.Warningf("Duplicated monitor configuration %q", config)
 			continue
 		}
-		monitors[config] = systemlogmonitor.NewLogMonitorOrDie(config)
+		m, err := systemlogmonitor.NewLogMonitor(config)
+		if err != nil {
+			// A single bad config should not disable every other monitor.
+			glog.Errorf("Skipping invalid system log monitor configuration %q: %v", config, err)
+			continue
+		}
+		monitors[config] = m
 	}
 
 	for _, config := range npdo.CustomPluginMonitorConfigPaths {
@@ -86,35 +114,82 @@ func main() {
 			glog.Warningf("Duplicated monitor configuration %q", config)
 			continue
 		}
-		monitors[config] = custompluginmonitor.NewCustomPluginMonitorOrDie(config)
+		m, err := custompluginmonitor.NewCustomPluginMonitor(config)
+		if err != nil {
+			// A single bad config should not disable every other monitor.
+			glog.Errorf("Skipping invalid custom plugin monitor configuration %q: %v", config, err)
+			continue
+		}
+		monitors[config] = m
 	}
-	c := problemclient.NewClientOrDie(npdo)
-	p := problemdetector.NewProblemDetector(monitors, c)
+
+	sinks := exporters.NewSinksOrDie(npdo.Exporters, npdo)
+
+	// Only sinks that depend on an external service (today, just the k8s
+	// sink's dependency on kube-apiserver) gate readiness and need to be
+	// waited on below; a pure stdout/file/syslog/http/kafka configuration
+	// can become ready as soon as its monitors are up.
+	waiters := []exporters.ReadinessWaiter{}
+	for _, sink := range sinks {
+		if waiter, ok := sink.(exporters.ReadinessWaiter); ok {
+			waiters = append(waiters, waiter)
+		}
+	}
+
+	monitorNames := make([]string, 0, len(monitors))
+	for name := range monitors {
+		monitorNames = append(monitorNames, name)
+	}
+	readinessChecker := readiness.NewChecker(monitorNames, len(waiters) > 0)
+
+	supervisorConfig := problemdetector.MonitorSupervisorConfig{
+		MinBackoff:       npdo.MonitorRestartMinBackoff,
+		MaxBackoff:       npdo.MonitorRestartMaxBackoff,
+		MaxRestarts:      npdo.MonitorMaxRestarts,
+		DegradeThreshold: npdo.MonitorDegradeThreshold,
+		DegradeWindow:    npdo.MonitorDegradeWindow,
+	}
+	p := problemdetector.NewProblemDetector(monitors, sinks, readinessChecker, npdo.APIServerRequestTimeout, supervisorConfig)
 
 	// Start http server.
 	if npdo.ServerPort > 0 {
-		startHTTPServer(p, npdo)
+		startHTTPServer(p, readinessChecker, npdo)
 	}
 
-	// This function may be blocked (until a timeout occurs) before
-	// kube-apiserver becomes ready.
-	glog.Infof("Waiting for kube-apiserver to be ready (timeout %v)...", npdo.APIServerWaitTimeout)
-	if err := waitForAPIServerReadyWithTimeout(c, npdo); err != nil {
-		glog.Warningf("kube-apiserver did not become ready: timed out on waiting for kube-apiserver to return the node object: %v", err)
+	// Start a dedicated prometheus metrics server, if requested. Otherwise
+	// /metrics is already served from the main http server above, via
+	// p.RegisterHTTPHandlers.
+	if npdo.PrometheusPort > 0 {
+		startPrometheusServer(npdo)
 	}
 
-	if err := p.Run(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	installSignalHandler(cancel)
+
+	for _, waiter := range waiters {
+		// This call may be blocked (until a timeout occurs) before
+		// kube-apiserver becomes ready.
+		glog.Infof("Waiting for kube-apiserver to be ready (timeout %v)...", npdo.APIServerWaitTimeout)
+		if err := waiter.WaitReady(ctx, npdo.APIServerWaitInterval, npdo.APIServerWaitTimeout); err != nil {
+			glog.Warningf("kube-apiserver did not become ready: timed out on waiting for kube-apiserver to return the node object: %v", err)
+		} else {
+			readinessChecker.SetAPIServerReady()
+		}
+	}
+
+	if err := p.Run(ctx); err != nil {
 		glog.Fatalf("Problem detector failed with error: %v", err)
 	}
 }
 
-func waitForAPIServerReadyWithTimeout(c problemclient.Client, npdo *options.NodeProblemDetectorOptions) error {
-	return wait.PollImmediate(npdo.APIServerWaitInterval, npdo.APIServerWaitTimeout, func() (done bool, err error) {
-		// If NPD can get the node object from kube-apiserver, the server is
-		// ready and the RBAC permission is set correctly.
-		if _, err := c.GetNode(); err == nil {
-			return true, nil
-		}
-		return false, nil
-	})
+// installSignalHandler cancels cancel when SIGTERM or SIGINT is received, so
+// that in-flight condition updates can flush before the process exits.
+func installSignalHandler(cancel context.CancelFunc) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		sig := <-sigCh
+		glog.Infof("Received signal %v, shutting down gracefully...", sig)
+		cancel()
+	}()
 }
@@ -0,0 +1,159 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/pflag"
+)
+
+// NodeProblemDetectorOptions contains node problem detector command line and
+// environment options.
+type NodeProblemDetectorOptions struct {
+	// SystemLogMonitorConfigPaths specifies the list of paths to system log monitor
+	// configuration files.
+	SystemLogMonitorConfigPaths []string
+	// CustomPluginMonitorConfigPaths specifies the list of paths to custom plugin
+	// monitor configuration files.
+	CustomPluginMonitorConfigPaths []string
+	// ApiServerOverride is the custom URI used to connect to the Kubernetes API server.
+	ApiServerOverride string
+	// PrintVersion is the flag determining whether version information is printed.
+	PrintVersion bool
+	// HostnameOverride specifies custom node name used to override hostname.
+	HostnameOverride string
+	// ServerPort is the port to bind the node problem detector server.
+	ServerPort int
+	// ServerAddress is the address to bind the node problem detector server.
+	ServerAddress string
+	// PrometheusPort is the port to bind the Prometheus metrics listener. If
+	// it is 0, metrics are served from the main server instead of a
+	// dedicated listener.
+	PrometheusPort int
+	// PrometheusAddress is the address to bind the Prometheus metrics
+	// listener.
+	PrometheusAddress string
+	// APIServerWaitTimeout is the timeout on waiting for kube-apiserver to be ready.
+	APIServerWaitTimeout time.Duration
+	// APIServerWaitInterval is the interval between the checks on the readiness of
+	// kube-apiserver.
+	APIServerWaitInterval time.Duration
+	// Exporters is the list of problem sinks node problem detector fans
+	// detected problems out to, e.g. "k8s,stdout,file:///var/log/npd.jsonl".
+	// Only the "k8s" exporter requires a reachable kube-apiserver.
+	Exporters []string
+	// APIServerRequestTimeout is the per-request deadline applied to
+	// steady-state condition/event updates sent to kube-apiserver, once it
+	// is up. It is separate from APIServerWaitInterval, which only bounds
+	// the initial readiness probe.
+	APIServerRequestTimeout time.Duration
+	// MonitorRestartMinBackoff is the delay before the first restart of a
+	// monitor that has crashed.
+	MonitorRestartMinBackoff time.Duration
+	// MonitorRestartMaxBackoff caps the delay between successive restarts of
+	// a monitor that keeps crashing.
+	MonitorRestartMaxBackoff time.Duration
+	// MonitorMaxRestarts is the number of times a crashed monitor may be
+	// restarted before the supervisor gives up on it.
+	MonitorMaxRestarts int
+	// MonitorDegradeThreshold is the number of crashes within
+	// MonitorDegradeWindow after which a monitor is marked degraded and
+	// /readyz starts reporting not ready.
+	MonitorDegradeThreshold int
+	// MonitorDegradeWindow is the sliding window monitor crashes are counted
+	// over when evaluating MonitorDegradeThreshold.
+	MonitorDegradeWindow time.Duration
+
+	nodeName string
+}
+
+// NewNodeProblemDetectorOptions creates a new NodeProblemDetectorOptions with
+// default values.
+func NewNodeProblemDetectorOptions() *NodeProblemDetectorOptions {
+	return &NodeProblemDetectorOptions{}
+}
+
+// AddFlags adds node problem detector command line options to pflag.
+func (npdo *NodeProblemDetectorOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&npdo.SystemLogMonitorConfigPaths, "system-log-monitors",
+		[]string{}, "List of paths to system log monitor config files, comma separated.")
+	fs.StringSliceVar(&npdo.CustomPluginMonitorConfigPaths, "custom-plugin-monitors",
+		[]string{}, "List of paths to custom plugin monitor config files, comma separated.")
+	fs.StringVar(&npdo.ApiServerOverride, "apiserver-override", "",
+		"Custom URI used to connect to Kubernetes ApiServer.")
+	fs.BoolVar(&npdo.PrintVersion, "version", false, "Print version information and quit")
+	fs.StringVar(&npdo.HostnameOverride, "hostname-override", "",
+		"Custom node name used to override hostname")
+	fs.IntVar(&npdo.ServerPort, "port", 20256,
+		"The port to bind the node problem detector server. Use 0 to disable.")
+	fs.StringVar(&npdo.ServerAddress, "address", "127.0.0.1",
+		"The address to bind the node problem detector server.")
+	fs.IntVar(&npdo.PrometheusPort, "prometheus-port", 0,
+		"The port to bind a dedicated Prometheus metrics listener on. Use 0 to serve /metrics "+
+			"from the main node problem detector server instead.")
+	fs.StringVar(&npdo.PrometheusAddress, "prometheus-address", "127.0.0.1",
+		"The address to bind the dedicated Prometheus metrics listener on.")
+	fs.DurationVar(&npdo.APIServerWaitTimeout, "apiserver-wait-timeout", time.Duration(5)*time.Minute,
+		"The timeout on waiting for kube-apiserver to be ready.")
+	fs.DurationVar(&npdo.APIServerWaitInterval, "apiserver-wait-interval", time.Duration(5)*time.Second,
+		"The interval between the checks on the readiness of kube-apiserver.")
+	fs.StringSliceVar(&npdo.Exporters, "exporter", []string{"k8s"},
+		"List of problem sinks to export detected problems to, comma separated. Supported "+
+			"schemes are \"k8s\", \"stdout\", \"syslog\", \"file:///path\", \"http(s)://host/path\" "+
+			"and \"kafka://broker/topic\".")
+	fs.DurationVar(&npdo.APIServerRequestTimeout, "apiserver-request-timeout", time.Duration(10)*time.Second,
+		"The per-request deadline for steady-state condition and event updates sent to kube-apiserver.")
+	fs.DurationVar(&npdo.MonitorRestartMinBackoff, "monitor-restart-min-backoff", time.Duration(1)*time.Second,
+		"The delay before the first restart of a monitor that has crashed.")
+	fs.DurationVar(&npdo.MonitorRestartMaxBackoff, "monitor-restart-max-backoff", time.Duration(5)*time.Minute,
+		"The maximum delay between successive restarts of a monitor that keeps crashing.")
+	fs.IntVar(&npdo.MonitorMaxRestarts, "monitor-max-restarts", 10,
+		"The number of times a crashed monitor may be restarted before node problem detector gives up on it.")
+	fs.IntVar(&npdo.MonitorDegradeThreshold, "monitor-degrade-threshold", 3,
+		"The number of crashes within --monitor-degrade-window after which a monitor is marked degraded and /readyz reports not ready.")
+	fs.DurationVar(&npdo.MonitorDegradeWindow, "monitor-degrade-window", time.Duration(10)*time.Minute,
+		"The sliding window monitor crashes are counted over when evaluating --monitor-degrade-threshold.")
+}
+
+// SetNodeNameOrDie sets NodeName field on npdo by reading os.Hostname or from the
+// HostnameOverride, and dies if no name could be resolved.
+func (npdo *NodeProblemDetectorOptions) SetNodeNameOrDie() {
+	if npdo.HostnameOverride != "" {
+		npdo.nodeName = npdo.HostnameOverride
+		return
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Fatalf("Failed to get hostname: %v", err)
+	}
+	npdo.nodeName = hostname
+}
+
+// NodeName returns the resolved node name.
+func (npdo *NodeProblemDetectorOptions) NodeName() string {
+	return npdo.nodeName
+}
+
+// ValidOrDie validates npdo and dies if any option is invalid.
+func (npdo *NodeProblemDetectorOptions) ValidOrDie() {
+	if len(npdo.SystemLogMonitorConfigPaths) == 0 && len(npdo.CustomPluginMonitorConfigPaths) == 0 {
+		glog.Fatalf("No configuration file specified.")
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package custompluginmonitor implements a monitor that periodically runs a
+// set of user supplied plugin scripts and translates their exit codes into
+// node problem detector statuses.
+package custompluginmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime/debug"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/metrics"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// Plugin describes a single plugin invocation.
+type Plugin struct {
+	// Name identifies the plugin in logs and metrics.
+	Name string `json:"name"`
+	// Path is the path to the plugin executable.
+	Path string `json:"path"`
+	// Args are the arguments passed to the plugin executable.
+	Args []string `json:"args"`
+	// Condition is the node condition the plugin affects.
+	Condition string `json:"condition"`
+	// TimeoutString is the per-invocation timeout, parsed with time.ParseDuration.
+	TimeoutString string `json:"timeout"`
+}
+
+// Config is the configuration of the custom plugin monitor.
+type Config struct {
+	// Source is the source name reported with every status.
+	Source string `json:"source"`
+	// InvokeIntervalString is the interval between plugin invocations, parsed
+	// with time.ParseDuration.
+	InvokeIntervalString string `json:"invokeInterval"`
+	// Plugins are the plugins this monitor invokes on each cycle.
+	Plugins []Plugin `json:"plugins"`
+}
+
+type customPluginMonitor struct {
+	configPath     string
+	config         Config
+	invokeInterval time.Duration
+	output         chan *types.Status
+	// conditions tracks the last reported state of each plugin's condition,
+	// keyed by Plugin.Condition, so that a plugin going from failing back to
+	// passing is reported as a transition to False instead of going silent.
+	conditions map[string]types.Condition
+}
+
+// NewCustomPluginMonitor creates a new custom plugin monitor from the config
+// file at configPath, returning an error instead of dying if the config
+// cannot be loaded, so that a single bad monitor configuration does not take
+// down the rest of node problem detector.
+func NewCustomPluginMonitor(configPath string) (types.Monitor, error) {
+	content, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %q: %v", configPath, err)
+	}
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration file %q: %v", configPath, err)
+	}
+	interval, err := time.ParseDuration(config.InvokeIntervalString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invoke interval %q in %q: %v", config.InvokeIntervalString, configPath, err)
+	}
+	conditions := make(map[string]types.Condition, len(config.Plugins))
+	now := time.Now()
+	for _, plugin := range config.Plugins {
+		if _, ok := conditions[plugin.Condition]; ok {
+			continue
+		}
+		conditions[plugin.Condition] = types.Condition{
+			Type:       plugin.Condition,
+			Status:     types.False,
+			Transition: now,
+			Reason:     "NoProblem",
+			Message:    "No problem detected yet",
+		}
+	}
+	return &customPluginMonitor{
+		configPath:     configPath,
+		config:         config,
+		invokeInterval: interval,
+		conditions:     conditions,
+	}, nil
+}
+
+// NewCustomPluginMonitorOrDie creates a new custom plugin monitor from the
+// config file at configPath, panics if the monitor cannot be created.
+func NewCustomPluginMonitorOrDie(configPath string) types.Monitor {
+	m, err := NewCustomPluginMonitor(configPath)
+	if err != nil {
+		glog.Fatalf("%v", err)
+	}
+	return m
+}
+
+// Start implements types.Monitor. It may be called again on the same
+// customPluginMonitor after its returned channel closes, which restarts the
+// invocation ticker; the supervisor in pkg/problemdetector does this after a
+// crash.
+func (c *customPluginMonitor) Start(ctx context.Context) (<-chan *types.Status, error) {
+	glog.Infof("Start custom plugin monitor %q", c.configPath)
+	c.output = make(chan *types.Status)
+	go c.monitorLoop(ctx)
+	return c.output, nil
+}
+
+// monitorLoop periodically invokes the configured plugins until ctx is
+// cancelled. A panic here is recovered so that a single misbehaving plugin
+// invocation cannot take down the whole process; the supervisor in
+// pkg/problemdetector notices the output channel closing while ctx is still
+// live and restarts the monitor.
+func (c *customPluginMonitor) monitorLoop(ctx context.Context) {
+	defer close(c.output)
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("Custom plugin monitor %q crashed: %v\n%s", c.config.Source, r, debug.Stack())
+		}
+	}()
+
+	ticker := time.NewTicker(c.invokeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, status := range c.runPlugins() {
+				select {
+				case c.output <- status:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *customPluginMonitor) runPlugins() []*types.Status {
+	statuses := make([]*types.Status, 0, len(c.config.Plugins))
+	for _, plugin := range c.config.Plugins {
+		timeout, err := time.ParseDuration(plugin.TimeoutString)
+		if err != nil {
+			glog.Errorf("Failed to parse timeout %q for plugin %q: %v", plugin.TimeoutString, plugin.Name, err)
+			continue
+		}
+		status := c.runPlugin(plugin, timeout)
+		if status != nil {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func (c *customPluginMonitor) runPlugin(plugin Plugin, timeout time.Duration) *types.Status {
+	done := make(chan error, 1)
+	cmd := exec.Command(plugin.Path, plugin.Args...)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		glog.Errorf("Failed to start plugin %q: %v", plugin.Name, err)
+		return nil
+	}
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		metrics.RegisterPluginExecution(c.config.Source, plugin.Name, time.Since(start).Seconds(), false)
+		if err == nil {
+			return c.problemStatus(plugin, types.False, "NoProblem", "Plugin reported no problem")
+		}
+		return c.problemStatus(plugin, types.True, plugin.Name, err.Error())
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		metrics.RegisterPluginExecution(c.config.Source, plugin.Name, time.Since(start).Seconds(), true)
+		return c.problemStatus(plugin, types.True, plugin.Name, context.DeadlineExceeded.Error())
+	}
+}
+
+// problemStatus records plugin's condition as status, updating Transition
+// only if the condition actually changed state since the last invocation,
+// so that a plugin going from failing back to passing is reported as a
+// transition to False rather than simply going quiet.
+func (c *customPluginMonitor) problemStatus(plugin Plugin, status types.ConditionStatus, reason, message string) *types.Status {
+	prev := c.conditions[plugin.Condition]
+	condition := types.Condition{
+		Type:       plugin.Condition,
+		Status:     status,
+		Transition: prev.Transition,
+		Reason:     reason,
+		Message:    message,
+	}
+	if prev.Status != status {
+		condition.Transition = time.Now()
+	}
+	c.conditions[plugin.Condition] = condition
+	return &types.Status{
+		Source:     c.config.Source,
+		Conditions: []types.Condition{condition},
+	}
+}
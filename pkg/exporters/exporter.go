@@ -0,0 +1,51 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exporters implements the pluggable problem sinks node problem
+// detector can fan detected problems out to: kube-apiserver node
+// conditions/events, stdout, a local file, syslog, an http collector or a
+// Kafka topic. This lets node problem detector run on nodes that are not
+// part of a Kubernetes cluster.
+package exporters
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// Sink is a destination node problem detector can export detected problems
+// to. Implementations must not block for long and must not let a failure
+// reaching the destination propagate to the caller, since a single slow or
+// unreachable sink should never stall the others. ctx only carries a
+// request-scoped timeout for this delivery attempt; it is rooted in a fresh
+// context.Background() by the caller so it is never cancelled by node
+// problem detector shutting down, letting in-flight deliveries still flush.
+type Sink interface {
+	// Export delivers status to the sink.
+	Export(ctx context.Context, status *types.Status)
+}
+
+// ReadinessWaiter is implemented by sinks that depend on an external
+// service (e.g. kube-apiserver) becoming reachable before node problem
+// detector should be considered ready. main only waits for, and readiness
+// only requires, sinks that implement this interface.
+type ReadinessWaiter interface {
+	// WaitReady blocks, polling at interval, until the sink's dependency is
+	// reachable, ctx is cancelled, or timeout elapses.
+	WaitReady(ctx context.Context, interval, timeout time.Duration) error
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/cmd/options"
+)
+
+// NewSinksOrDie builds the configured set of problem sinks from the
+// --exporter flag values, e.g. "k8s", "stdout", "syslog",
+// "file:///var/log/npd.jsonl", "http://collector.example.com/problems" or
+// "kafka://broker:9092/problems". Panics if any spec is invalid.
+func NewSinksOrDie(specs []string, npdo *options.NodeProblemDetectorOptions) []Sink {
+	sinks := make([]Sink, 0, len(specs))
+	for _, spec := range specs {
+		sinks = append(sinks, newSinkOrDie(spec, npdo))
+	}
+	return sinks
+}
+
+func newSinkOrDie(spec string, npdo *options.NodeProblemDetectorOptions) Sink {
+	switch {
+	case spec == "k8s":
+		return NewK8sSinkOrDie(npdo)
+	case spec == "stdout":
+		return NewStdoutSink()
+	case spec == "syslog":
+		return NewSyslogSinkOrDie()
+	case strings.HasPrefix(spec, "file://"):
+		return NewFileSinkOrDie(strings.TrimPrefix(spec, "file://"))
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewHTTPSink(spec)
+	case strings.HasPrefix(spec, "kafka://"):
+		broker, topic, err := parseKafkaSpec(spec)
+		if err != nil {
+			glog.Fatalf("%v", err)
+		}
+		return NewKafkaSinkOrDie([]string{broker}, topic)
+	default:
+		glog.Fatalf("Unrecognized exporter spec %q", spec)
+		return nil
+	}
+}
+
+// parseKafkaSpec extracts the broker and topic from a "kafka://broker/topic"
+// exporter spec.
+func parseKafkaSpec(spec string) (broker, topic string, err error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse exporter spec %q: %v", spec, err)
+	}
+	topic = strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || topic == "" {
+		return "", "", fmt.Errorf("exporter spec %q must be of the form kafka://broker/topic", spec)
+	}
+	return u.Host, topic, nil
+}
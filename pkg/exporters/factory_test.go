@@ -0,0 +1,41 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import "testing"
+
+func TestParseKafkaSpec(t *testing.T) {
+	broker, topic, err := parseKafkaSpec("kafka://broker.example.com:9092/node-problems")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if broker != "broker.example.com:9092" || topic != "node-problems" {
+		t.Fatalf("got broker %q topic %q, want broker.example.com:9092 / node-problems", broker, topic)
+	}
+}
+
+func TestParseKafkaSpecMissingTopic(t *testing.T) {
+	if _, _, err := parseKafkaSpec("kafka://broker.example.com:9092"); err == nil {
+		t.Fatalf("expected an error for a spec with no topic")
+	}
+}
+
+func TestParseKafkaSpecMissingBroker(t *testing.T) {
+	if _, _, err := parseKafkaSpec("kafka:///node-problems"); err == nil {
+		t.Fatalf("expected an error for a spec with no broker")
+	}
+}
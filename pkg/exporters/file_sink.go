@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// fileSink appends each status as a line of JSON to a local file.
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSinkOrDie creates a sink that appends statuses to the file at path,
+// creating it if necessary.
+func NewFileSinkOrDie(path string) Sink {
+	return &fileSink{path: path}
+}
+
+func (f *fileSink) Export(ctx context.Context, status *types.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		glog.Errorf("Failed to marshal status from source %q: %v", status.Source, err)
+		return
+	}
+	data = append(data, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		glog.Errorf("Failed to open file sink %q: %v", f.path, err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		glog.Errorf("Failed to write to file sink %q: %v", f.path, err)
+	}
+}
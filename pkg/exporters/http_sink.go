@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// httpSink POSTs each status as JSON to a collector endpoint.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink that POSTs statuses to url.
+func NewHTTPSink(url string) Sink {
+	return &httpSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (h *httpSink) Export(ctx context.Context, status *types.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		glog.Errorf("Failed to marshal status from source %q: %v", status.Source, err)
+		return
+	}
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(data))
+	if err != nil {
+		glog.Errorf("Failed to build request for %q: %v", h.url, err)
+		return
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		glog.Errorf("Failed to export status to %q: %v", h.url, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		glog.Errorf("Sink %q rejected status with code %d", h.url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"k8s.io/node-problem-detector/cmd/options"
+	"k8s.io/node-problem-detector/pkg/problemclient"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// k8sSink reports problems as kube-apiserver node conditions and events.
+type k8sSink struct {
+	client problemclient.Client
+}
+
+// NewK8sSinkOrDie creates a sink that reports problems to kube-apiserver,
+// panics if the underlying client cannot be created.
+func NewK8sSinkOrDie(npdo *options.NodeProblemDetectorOptions) Sink {
+	return &k8sSink{client: problemclient.NewClientOrDie(npdo)}
+}
+
+func (k *k8sSink) Export(ctx context.Context, status *types.Status) {
+	for _, event := range status.Events {
+		k.client.Eventf(ctx, eventTypeFromSeverity(event.Severity), status.Source, event.Reason, event.Message)
+	}
+	if len(status.Conditions) == 0 {
+		return
+	}
+	conditions := make([]v1.NodeCondition, 0, len(status.Conditions))
+	for _, condition := range status.Conditions {
+		conditions = append(conditions, toV1Condition(condition))
+	}
+	if err := k.client.SetConditions(ctx, conditions); err != nil {
+		glog.Errorf("Failed to update node conditions for source %q: %v", status.Source, err)
+	}
+}
+
+// WaitReady blocks, on a per-attempt deadline of interval, until
+// kube-apiserver returns the local node object (confirming both
+// connectivity and that the RBAC permissions are set correctly), ctx is
+// cancelled, or timeout elapses.
+func (k *k8sSink) WaitReady(ctx context.Context, interval, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(interval, func() (done bool, err error) {
+		attemptCtx, attemptCancel := context.WithTimeout(waitCtx, interval)
+		defer attemptCancel()
+		if _, err := k.client.GetNode(attemptCtx); err == nil {
+			return true, nil
+		}
+		return false, nil
+	}, waitCtx.Done())
+}
+
+func eventTypeFromSeverity(severity types.Severity) string {
+	if severity == types.Warn {
+		return v1.EventTypeWarning
+	}
+	return v1.EventTypeNormal
+}
+
+func toV1Condition(condition types.Condition) v1.NodeCondition {
+	return v1.NodeCondition{
+		Type:               v1.NodeConditionType(condition.Type),
+		Status:             v1.ConditionStatus(condition.Status),
+		LastTransitionTime: metav1.NewTime(condition.Transition),
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+	}
+}
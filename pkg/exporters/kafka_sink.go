@@ -0,0 +1,69 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// kafkaSink produces each status as a JSON message to a Kafka topic.
+type kafkaSink struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewKafkaSinkOrDie creates a sink that produces statuses to topic on the
+// given Kafka brokers, panics if a producer cannot be created.
+func NewKafkaSinkOrDie(brokers []string, topic string) Sink {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		glog.Fatalf("Failed to create kafka producer for %v: %v", brokers, err)
+	}
+	return &kafkaSink{producer: producer, topic: topic}
+}
+
+func (k *kafkaSink) Export(ctx context.Context, status *types.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		glog.Errorf("Failed to marshal status from source %q: %v", status.Source, err)
+		return
+	}
+	msg := &sarama.ProducerMessage{Topic: k.topic, Value: sarama.ByteEncoder(data)}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := k.producer.SendMessage(msg)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			glog.Errorf("Failed to send status from source %q to kafka topic %q: %v", status.Source, k.topic, err)
+		}
+	case <-ctx.Done():
+		glog.Errorf("Timed out sending status from source %q to kafka topic %q: %v", status.Source, k.topic, ctx.Err())
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// stdoutSink writes each status as a single line of JSON to stdout. Useful
+// for running node problem detector outside a cluster and piping its output
+// into another log collector.
+type stdoutSink struct{}
+
+// NewStdoutSink creates a sink that prints statuses to stdout.
+func NewStdoutSink() Sink {
+	return &stdoutSink{}
+}
+
+func (s *stdoutSink) Export(ctx context.Context, status *types.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		glog.Errorf("Failed to marshal status from source %q: %v", status.Source, err)
+		return
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporters
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// syslogSink writes each status as a JSON message to the local syslog
+// daemon.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSinkOrDie creates a sink that writes statuses to syslog, panics
+// if the connection to the syslog daemon cannot be established.
+func NewSyslogSinkOrDie() Sink {
+	writer, err := syslog.New(syslog.LOG_WARNING|syslog.LOG_DAEMON, "node-problem-detector")
+	if err != nil {
+		glog.Fatalf("Failed to connect to syslog: %v", err)
+	}
+	return &syslogSink{writer: writer}
+}
+
+func (s *syslogSink) Export(ctx context.Context, status *types.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		glog.Errorf("Failed to marshal status from source %q: %v", status.Source, err)
+		return
+	}
+	if err := s.writer.Warning(string(data)); err != nil {
+		glog.Errorf("Failed to write to syslog: %v", err)
+	}
+}
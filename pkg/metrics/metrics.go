@@ -0,0 +1,147 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exports node problem detector's internal state as
+// Prometheus metrics: problems detected by monitor, current node condition
+// states, and per-monitor operational stats (log lines scanned, plugin
+// executions/timeouts, plugin exec durations).
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "problem_detector"
+
+var (
+	// ProblemCounter counts the number of problems detected, labeled by the
+	// monitor, the problem reason, whether it was a "temporary" event or a
+	// "permanent" condition, and (for events) its severity ("info"/"warn").
+	// Conditions have no severity of their own, so they report an empty
+	// severity label rather than reusing "permanent" for both fields.
+	ProblemCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "problems_total",
+		Help:      "Number of node problems detected, partitioned by monitor, reason, kind and severity.",
+	}, []string{"monitor", "reason", "kind", "severity"})
+
+	// ProblemGauge reflects the current state (1 = active, 0 = cleared) of
+	// every permanent node condition, labeled by monitor and condition type.
+	ProblemGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "problem_state",
+		Help:      "Whether a node condition is currently active (1) or not (0), partitioned by monitor and condition.",
+	}, []string{"monitor", "condition", "reason"})
+
+	// LogLinesScanned counts the number of log lines scanned by each system
+	// log monitor.
+	LogLinesScanned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "log_lines_scanned_total",
+		Help:      "Number of log lines scanned, partitioned by monitor.",
+	}, []string{"monitor"})
+
+	// PluginExecutions counts the number of times a custom plugin has been
+	// invoked.
+	PluginExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_executions_total",
+		Help:      "Number of custom plugin invocations, partitioned by monitor and plugin.",
+	}, []string{"monitor", "plugin"})
+
+	// PluginTimeouts counts the number of custom plugin invocations that hit
+	// their timeout.
+	PluginTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_timeouts_total",
+		Help:      "Number of custom plugin invocations that timed out, partitioned by monitor and plugin.",
+	}, []string{"monitor", "plugin"})
+
+	// PluginExecutionDuration is a histogram of custom plugin execution
+	// durations in seconds.
+	PluginExecutionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plugin_execution_duration_seconds",
+		Help:      "Duration in seconds of custom plugin invocations, partitioned by monitor and plugin.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"monitor", "plugin"})
+
+	// MonitorPanics counts the number of times the supervisor in
+	// pkg/problemdetector has seen a monitor crash, whether or not it then
+	// restarts the monitor. It uses the "npd"
+	// namespace rather than the usual "problem_detector" one, matching the
+	// npd_monitor_panics_total name operators and alerts are built against.
+	MonitorPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "npd",
+		Name:      "monitor_panics_total",
+		Help:      "Number of times a monitor has crashed, partitioned by monitor.",
+	}, []string{"monitor"})
+)
+
+func init() {
+	prometheus.MustRegister(ProblemCounter)
+	prometheus.MustRegister(ProblemGauge)
+	prometheus.MustRegister(LogLinesScanned)
+	prometheus.MustRegister(PluginExecutions)
+	prometheus.MustRegister(PluginTimeouts)
+	prometheus.MustRegister(PluginExecutionDuration)
+	prometheus.MustRegister(MonitorPanics)
+}
+
+// RegisterProblem records that monitor detected a problem with the given
+// reason, kind ("temporary" event or "permanent" condition) and severity.
+// Conditions have no severity, so callers should pass "" for them.
+func RegisterProblem(monitor, reason, kind, severity string) {
+	ProblemCounter.WithLabelValues(monitor, reason, kind, severity).Inc()
+}
+
+// SetConditionState records whether the given permanent condition from
+// monitor is currently active.
+func SetConditionState(monitor, condition, reason string, active bool) {
+	value := 0.0
+	if active {
+		value = 1.0
+	}
+	ProblemGauge.WithLabelValues(monitor, condition, reason).Set(value)
+}
+
+// RegisterLogLineScanned records that monitor scanned one more log line.
+func RegisterLogLineScanned(monitor string) {
+	LogLinesScanned.WithLabelValues(monitor).Inc()
+}
+
+// RegisterPluginExecution records a plugin invocation and, on timeout, bumps
+// the timeout counter as well.
+func RegisterPluginExecution(monitor, plugin string, duration float64, timedOut bool) {
+	PluginExecutions.WithLabelValues(monitor, plugin).Inc()
+	PluginExecutionDuration.WithLabelValues(monitor, plugin).Observe(duration)
+	if timedOut {
+		PluginTimeouts.WithLabelValues(monitor, plugin).Inc()
+	}
+}
+
+// RegisterMonitorPanic records that monitor crashed.
+func RegisterMonitorPanic(monitor string) {
+	MonitorPanics.WithLabelValues(monitor).Inc()
+}
+
+// Handler returns the http.Handler that serves the Prometheus metrics page.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/node-problem-detector/cmd/options"
+)
+
+// Client is the interface node problem detector uses to report node problems
+// to the Kubernetes API server. Every method takes a context. For GetNode
+// and SetConditions a caller-supplied deadline (or cancellation, e.g. on
+// SIGTERM) bounds both the TLS handshake and the HTTP round-trip of the
+// underlying request; Eventf does not use its context.
+type Client interface {
+	// GetNode returns the node object of the local node.
+	GetNode(ctx context.Context) (*v1.Node, error)
+	// SetConditions updates the node conditions.
+	SetConditions(ctx context.Context, conditions []v1.NodeCondition) error
+	// Eventf reports an event.
+	Eventf(ctx context.Context, eventType string, source, reason, messageFmt string, args ...interface{})
+}
+
+type nodeProblemClient struct {
+	nodeName  string
+	client    kubernetes.Interface
+	recorders map[string]record.EventRecorder
+	nodeRef   *v1.ObjectReference
+}
+
+// NewClientOrDie creates a new problem client, panics if error occurs.
+func NewClientOrDie(npdo *options.NodeProblemDetectorOptions) Client {
+	cfg, err := clientcmd.BuildConfigFromFlags(npdo.ApiServerOverride, "")
+	if err != nil {
+		glog.Fatalf("Failed to build kube client config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		glog.Fatalf("Failed to create kube client: %v", err)
+	}
+	nodeRef := getNodeRef(npdo.NodeName())
+	return &nodeProblemClient{
+		nodeName:  npdo.NodeName(),
+		client:    client,
+		recorders: make(map[string]record.EventRecorder),
+		nodeRef:   nodeRef,
+	}
+}
+
+func getNodeRef(nodeName string) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind: "Node",
+		Name: nodeName,
+		UID:  types.UID(nodeName),
+	}
+}
+
+func (c *nodeProblemClient) GetNode(ctx context.Context) (*v1.Node, error) {
+	node := &v1.Node{}
+	err := c.client.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(c.nodeName).
+		Timeout(timeoutFromContext(ctx)).
+		Context(ctx).
+		Do().
+		Into(node)
+	return node, err
+}
+
+func (c *nodeProblemClient) SetConditions(ctx context.Context, conditions []v1.NodeCondition) error {
+	node, err := c.GetNode(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get node %q: %v", c.nodeName, err)
+	}
+	for _, condition := range conditions {
+		updateCondition(node, condition)
+	}
+	return c.client.CoreV1().RESTClient().Put().
+		Resource("nodes").
+		Name(c.nodeName).
+		SubResource("status").
+		Body(node).
+		Timeout(timeoutFromContext(ctx)).
+		Context(ctx).
+		Do().
+		Error()
+}
+
+func updateCondition(node *v1.Node, condition v1.NodeCondition) {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == condition.Type {
+			node.Status.Conditions[i] = condition
+			return
+		}
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+}
+
+// Eventf reports an event. The underlying event recorder dispatches
+// asynchronously and has no per-call context hook, so ctx is not used:
+// neither its deadline nor its cancellation bounds the event's delivery.
+func (c *nodeProblemClient) Eventf(ctx context.Context, eventType string, source, reason, messageFmt string, args ...interface{}) {
+	recorder, found := c.recorders[source]
+	if !found {
+		recorder = getEventRecorder(c.client, c.nodeName, source)
+		c.recorders[source] = recorder
+	}
+	recorder.Eventf(c.nodeRef, eventType, reason, messageFmt, args...)
+}
+
+func getEventRecorder(client kubernetes.Interface, nodeName, source string) record.EventRecorder {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedv1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: source, Host: nodeName})
+}
+
+// timeoutFromContext returns the duration until ctx's deadline, or 0 (no
+// request timeout) if ctx carries none.
+func timeoutFromContext(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline)
+}
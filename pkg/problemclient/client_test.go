@@ -0,0 +1,39 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFromContextNoDeadline(t *testing.T) {
+	if got := timeoutFromContext(context.Background()); got != 0 {
+		t.Fatalf("got %v, want 0 for a context with no deadline", got)
+	}
+}
+
+func TestTimeoutFromContextWithDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	got := timeoutFromContext(ctx)
+	if got <= 0 || got > time.Minute {
+		t.Fatalf("got %v, want a positive duration up to 1m", got)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemdetector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/exporters"
+	"k8s.io/node-problem-detector/pkg/metrics"
+	"k8s.io/node-problem-detector/pkg/readiness"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// ProblemDetector collects statuses from all the monitors and fans them out
+// to the configured sinks.
+type ProblemDetector interface {
+	// Run starts the problem detector and blocks until every monitor's
+	// status channel has closed: once ctx is cancelled, or earlier if no
+	// monitor is configured or the supervisor has given up on every monitor.
+	// It returns an error if any of the monitors fails to start.
+	Run(ctx context.Context) error
+	// RegisterHTTPHandlers registers additional http handlers problem
+	// detector needs to serve.
+	RegisterHTTPHandlers()
+}
+
+type problemDetector struct {
+	monitors         map[string]types.Monitor
+	sinks            []exporters.Sink
+	readiness        *readiness.Checker
+	requestTimeout   time.Duration
+	supervisorConfig MonitorSupervisorConfig
+}
+
+// NewProblemDetector creates a new problem detector. readinessChecker is
+// notified as each monitor starts; it is not told when a monitor produces
+// its first status. requestTimeout bounds
+// each individual sink export call, so a slow or unreachable sink cannot
+// stall the fan-out of the rest. supervisorConfig controls how a crashed
+// monitor is restarted.
+func NewProblemDetector(monitors map[string]types.Monitor, sinks []exporters.Sink, readinessChecker *readiness.Checker, requestTimeout time.Duration, supervisorConfig MonitorSupervisorConfig) ProblemDetector {
+	return &problemDetector{
+		monitors:         monitors,
+		sinks:            sinks,
+		readiness:        readinessChecker,
+		requestTimeout:   requestTimeout,
+		supervisorConfig: supervisorConfig,
+	}
+}
+
+// RegisterHTTPHandlers registers additional http handlers problem detector needs
+// to serve.
+func (p *problemDetector) RegisterHTTPHandlers() {
+	http.Handle("/metrics", metrics.Handler())
+}
+
+// Run starts the problem detector.
+func (p *problemDetector) Run(ctx context.Context) error {
+	channels := []<-chan *types.Status{}
+	for name, m := range p.monitors {
+		ch, err := superviseMonitor(ctx, name, m, p.supervisorConfig, p.readiness)
+		if err != nil {
+			return err
+		}
+		glog.Infof("Problem monitor %q started", name)
+		// The monitor's config is loaded and its run loop is live, so mark
+		// it ready now rather than waiting for a first status: most
+		// monitors produce no status at all while the node is healthy, and
+		// waiting for one would keep /readyz failing forever in that case.
+		p.readiness.SetMonitorReady(name)
+		channels = append(channels, ch)
+	}
+
+	ch := groupChannel(channels)
+	for status := range ch {
+		p.sync(status)
+	}
+	return nil
+}
+
+// sync fans status out to every configured sink, bounding each export with
+// its own request-scoped deadline so that one slow sink does not hold up
+// the others or the detector's shutdown. The deadline is rooted in a fresh
+// context rather than Run's ctx: ctx is already cancelled by the time
+// shutdown statuses are flushing, and a sink export scoped to a cancelled
+// context would fail instantly, defeating the "flush in-flight updates
+// before exiting" guarantee.
+func (p *problemDetector) sync(status *types.Status) {
+	for _, sink := range p.sinks {
+		reqCtx, cancel := context.WithTimeout(context.Background(), p.requestTimeout)
+		sink.Export(reqCtx, status)
+		cancel()
+	}
+	for _, event := range status.Events {
+		metrics.RegisterProblem(status.Source, event.Reason, "temporary", string(event.Severity))
+	}
+	for _, condition := range status.Conditions {
+		if condition.Status == types.True {
+			metrics.RegisterProblem(status.Source, condition.Reason, "permanent", "")
+		}
+		metrics.SetConditionState(status.Source, condition.Type, condition.Reason, condition.Status == types.True)
+	}
+}
+
+// groupChannel merges a list of status channels into a single channel. The
+// returned channel is closed once every input channel has been closed, which
+// lets Run's consuming loop return once all monitors have shut down and
+// flushed their pending statuses.
+func groupChannel(channels []<-chan *types.Status) <-chan *types.Status {
+	out := make(chan *types.Status)
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+	for _, ch := range channels {
+		go func(ch <-chan *types.Status) {
+			defer wg.Done()
+			for status := range ch {
+				out <- status
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemdetector
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/metrics"
+	"k8s.io/node-problem-detector/pkg/readiness"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// MonitorSupervisorConfig controls how a crashed monitor is restarted.
+type MonitorSupervisorConfig struct {
+	// MinBackoff is the delay before the first restart attempt.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between successive restart attempts.
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of times a monitor may be restarted before
+	// the supervisor gives up on it and lets it stay down.
+	MaxRestarts int
+	// DegradeThreshold is the number of crashes within DegradeWindow after
+	// which the monitor is marked degraded in the readiness handler.
+	DegradeThreshold int
+	// DegradeWindow is the sliding window crashes are counted over when
+	// deciding whether a monitor has crossed DegradeThreshold.
+	DegradeWindow time.Duration
+}
+
+// superviseMonitor starts m and forwards every status it produces onto the
+// returned channel. A monitor's own statuses channel is only ever closed
+// from the monitor's run loop: normally that only happens once ctx is
+// cancelled, but a monitor that recovers from an internal panic also closes
+// it while ctx is still live. superviseMonitor treats that case as a crash
+// and restarts the monitor with exponential backoff and jitter, up to
+// cfg.MaxRestarts, recording the crash against npd_monitor_panics_total and
+// marking the monitor degraded in readinessChecker once it has crashed
+// cfg.DegradeThreshold times within cfg.DegradeWindow. Once a restarted
+// monitor has run without crashing for cfg.DegradeWindow, its crash history
+// is forgotten and any degraded mark is cleared.
+func superviseMonitor(ctx context.Context, name string, m types.Monitor, cfg MonitorSupervisorConfig, readinessChecker *readiness.Checker) (<-chan *types.Status, error) {
+	ch, err := m.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *types.Status)
+	go func() {
+		defer close(out)
+
+		backoff := cfg.MinBackoff
+		var crashes []time.Time
+		for restarts := 0; ; {
+			recovered := forward(ctx, ch, out, cfg.DegradeWindow)
+			if recovered {
+				// The monitor ran for cfg.DegradeWindow without crashing:
+				// it has recovered, so forget its crash history and keep
+				// forwarding until it actually closes or ctx is done.
+				crashes = nil
+				readinessChecker.ClearMonitorDegraded(name)
+				forward(ctx, ch, out, 0)
+			}
+			if ctx.Err() != nil {
+				// Shutting down: the channel closed because ctx was
+				// cancelled, not because the monitor crashed.
+				return
+			}
+
+			metrics.RegisterMonitorPanic(name)
+			crashes = recentCrashes(append(crashes, time.Now()), cfg.DegradeWindow)
+			if len(crashes) >= cfg.DegradeThreshold {
+				readinessChecker.SetMonitorDegraded(name)
+			}
+
+			restarts++
+			if restarts > cfg.MaxRestarts {
+				glog.Errorf("Monitor %q crashed %d times, giving up on restarting it", name, restarts)
+				return
+			}
+			delay := jitter(backoff)
+			glog.Warningf("Monitor %q crashed, restarting in %v (restart %d/%d)", name, delay, restarts, cfg.MaxRestarts)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			if backoff *= 2; backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+
+			ch, err = m.Start(ctx)
+			if err != nil {
+				glog.Errorf("Failed to restart monitor %q: %v", name, err)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// forward copies statuses from ch to out until ch closes or ctx is
+// cancelled, returning false in either case. If healthyWindow is positive
+// and ch stays open for at least that long, forward instead returns true
+// early without waiting for ch to close, letting the caller treat the
+// monitor as recovered and keep forwarding from the same ch separately.
+// healthyWindow of 0 disables this early return.
+func forward(ctx context.Context, ch <-chan *types.Status, out chan<- *types.Status, healthyWindow time.Duration) bool {
+	var healthy <-chan time.Time
+	if healthyWindow > 0 {
+		timer := time.NewTimer(healthyWindow)
+		defer timer.Stop()
+		healthy = timer.C
+	}
+	for {
+		select {
+		case status, ok := <-ch:
+			if !ok {
+				return false
+			}
+			select {
+			case out <- status:
+			case <-ctx.Done():
+				return false
+			}
+		case <-healthy:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// recentCrashes returns the crash timestamps in crashes that fall within
+// window of the most recent one, dropping older entries.
+func recentCrashes(crashes []time.Time, window time.Duration) []time.Time {
+	cutoff := crashes[len(crashes)-1].Add(-window)
+	i := 0
+	for i < len(crashes) && crashes[i].Before(cutoff) {
+		i++
+	}
+	return crashes[i:]
+}
+
+// jitter returns d plus up to 20% random jitter, so that monitors crashing
+// around the same time do not all retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemdetector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+func TestRecentCrashesDropsOlderEntries(t *testing.T) {
+	now := time.Now()
+	crashes := []time.Time{
+		now.Add(-time.Hour),
+		now.Add(-time.Minute),
+		now,
+	}
+	got := recentCrashes(crashes, 2*time.Minute)
+	if len(got) != 2 {
+		t.Fatalf("got %d recent crashes, want 2: %v", len(got), got)
+	}
+	if !got[0].Equal(crashes[1]) || !got[1].Equal(crashes[2]) {
+		t.Fatalf("got %v, want the last two crash timestamps", got)
+	}
+}
+
+func TestRecentCrashesKeepsEverythingWithinWindow(t *testing.T) {
+	now := time.Now()
+	crashes := []time.Time{now.Add(-time.Second), now}
+	got := recentCrashes(crashes, time.Minute)
+	if len(got) != 2 {
+		t.Fatalf("got %d recent crashes, want 2", len(got))
+	}
+}
+
+func TestJitterAddsUpToTwentyPercent(t *testing.T) {
+	d := 10 * time.Second
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		if got < d || got > d+d/5 {
+			t.Fatalf("jitter(%v) = %v, want within [%v, %v]", d, got, d, d+d/5)
+		}
+	}
+}
+
+func TestForwardReturnsFalseOnChannelClose(t *testing.T) {
+	ch := make(chan *types.Status)
+	out := make(chan *types.Status)
+	close(ch)
+
+	done := make(chan bool)
+	go func() {
+		done <- forward(context.Background(), ch, out, 0)
+	}()
+
+	select {
+	case recovered := <-done:
+		if recovered {
+			t.Fatalf("expected forward to return false when ch closes")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("forward did not return after ch closed")
+	}
+}
+
+func TestForwardReturnsTrueAfterHealthyWindow(t *testing.T) {
+	ch := make(chan *types.Status)
+	out := make(chan *types.Status)
+
+	done := make(chan bool)
+	go func() {
+		done <- forward(context.Background(), ch, out, 10*time.Millisecond)
+	}()
+
+	select {
+	case recovered := <-done:
+		if !recovered {
+			t.Fatalf("expected forward to return true once the healthy window elapses")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("forward did not return after the healthy window elapsed")
+	}
+}
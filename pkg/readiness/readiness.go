@@ -0,0 +1,126 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package readiness tracks node problem detector's liveness and readiness,
+// modeled on kube-apiserver's /livez vs /readyz split: /livez reflects
+// process liveness, /readyz only returns ok once every dependency the
+// problem detector needs to do useful work has come up.
+package readiness
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Checker tracks whether node problem detector is ready to serve, i.e. the
+// apiserver connection (if any) is up and every configured monitor has been
+// reported ready by its caller through SetMonitorReady.
+type Checker struct {
+	mu sync.Mutex
+
+	apiServerRequired bool
+	apiServerReady    bool
+
+	monitors map[string]bool
+	degraded map[string]bool
+}
+
+// NewChecker creates a readiness Checker for the given set of monitor names.
+// apiServerRequired should be true whenever node problem detector depends on
+// a reachable kube-apiserver to be considered ready.
+func NewChecker(monitorNames []string, apiServerRequired bool) *Checker {
+	monitors := make(map[string]bool, len(monitorNames))
+	for _, name := range monitorNames {
+		monitors[name] = false
+	}
+	return &Checker{
+		apiServerRequired: apiServerRequired,
+		monitors:          monitors,
+		degraded:          make(map[string]bool),
+	}
+}
+
+// SetAPIServerReady records that the kube-apiserver connection is up.
+func (c *Checker) SetAPIServerReady() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiServerReady = true
+}
+
+// SetMonitorReady records that the named monitor is up. The problem
+// detector calls it as soon as the monitor has started, without waiting for
+// the monitor's first status.
+func (c *Checker) SetMonitorReady(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.monitors[name] = true
+}
+
+// SetMonitorDegraded records that the named monitor has crashed repeatedly
+// and should no longer count towards readiness until it recovers. It is set
+// by the monitor supervisor in pkg/problemdetector.
+func (c *Checker) SetMonitorDegraded(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.degraded[name] = true
+}
+
+// ClearMonitorDegraded clears a previous SetMonitorDegraded call once name
+// has been running cleanly again for a while, letting /readyz report ready
+// once more.
+func (c *Checker) ClearMonitorDegraded(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.degraded, name)
+}
+
+// Ready returns whether node problem detector is ready to serve.
+func (c *Checker) Ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.apiServerRequired && !c.apiServerReady {
+		return false
+	}
+	for name, ready := range c.monitors {
+		if !ready || c.degraded[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// LivezHandler always returns ok once the process is up and serving http
+// requests; it never depends on upstream dependencies like kube-apiserver.
+func (c *Checker) LivezHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}
+}
+
+// ReadyzHandler returns ok only once Ready() is true, and service
+// unavailable otherwise.
+func (c *Checker) ReadyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("not ready"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import "testing"
+
+func TestReadyRequiresEveryMonitorAndApiServer(t *testing.T) {
+	c := NewChecker([]string{"a", "b"}, true)
+	if c.Ready() {
+		t.Fatalf("expected not ready before anything has reported in")
+	}
+
+	c.SetMonitorReady("a")
+	c.SetMonitorReady("b")
+	if c.Ready() {
+		t.Fatalf("expected not ready before the apiserver is reachable")
+	}
+
+	c.SetAPIServerReady()
+	if !c.Ready() {
+		t.Fatalf("expected ready once every monitor and the apiserver are up")
+	}
+}
+
+func TestReadyWithoutApiServerDependency(t *testing.T) {
+	c := NewChecker([]string{"a"}, false)
+	if c.Ready() {
+		t.Fatalf("expected not ready before the monitor has reported in")
+	}
+	c.SetMonitorReady("a")
+	if !c.Ready() {
+		t.Fatalf("expected ready once the only monitor is up, apiserver not required")
+	}
+}
+
+func TestDegradedMonitorBlocksReady(t *testing.T) {
+	c := NewChecker([]string{"a"}, false)
+	c.SetMonitorReady("a")
+	if !c.Ready() {
+		t.Fatalf("expected ready once the monitor is up")
+	}
+
+	c.SetMonitorDegraded("a")
+	if c.Ready() {
+		t.Fatalf("expected not ready once the monitor is degraded")
+	}
+}
+
+func TestClearMonitorDegradedRestoresReady(t *testing.T) {
+	c := NewChecker([]string{"a"}, false)
+	c.SetMonitorReady("a")
+	c.SetMonitorDegraded("a")
+	if c.Ready() {
+		t.Fatalf("expected not ready once the monitor is degraded")
+	}
+
+	c.ClearMonitorDegraded("a")
+	if !c.Ready() {
+		t.Fatalf("expected ready again once the degraded mark is cleared")
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package systemlogmonitor implements a monitor that watches a system log
+// file, matches new lines against a set of configured rules, and reports the
+// matched problems to node problem detector.
+package systemlogmonitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/node-problem-detector/pkg/metrics"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// Rule describes how a log line is translated into a temporary or permanent
+// problem.
+type Rule struct {
+	// Type is either "temporary" (reported as an event) or "permanent"
+	// (reported as a node condition).
+	Type string `json:"type"`
+	// Condition is the node condition the rule affects, only used when Type
+	// is "permanent".
+	Condition string `json:"condition"`
+	// Reason is the short reason reported when the rule matches.
+	Reason string `json:"reason"`
+	// Pattern is the regular expression matched against each log line.
+	Pattern string `json:"pattern"`
+	// Status is the condition status this rule sets when it matches
+	// ("True" or "False"); only meaningful when Type is "permanent".
+	// Defaults to "True" so existing configs that only describe problem
+	// patterns keep reporting the condition as active. A config can add a
+	// second rule with the same Condition and Status "False" matching the
+	// log line that signals the problem has cleared.
+	Status string `json:"status"`
+}
+
+// Config is the configuration of the log monitor.
+type Config struct {
+	// LogPath is the path of the log file to watch.
+	LogPath string `json:"logPath"`
+	// Source is the source name reported with every status.
+	Source string `json:"source"`
+	// Rules are the rules used to parse the log lines.
+	Rules []Rule `json:"rules"`
+}
+
+type logMonitor struct {
+	configPath string
+	config     Config
+	rules      []compiledRule
+	output     chan *types.Status
+	// conditions tracks the last reported state of each permanent
+	// condition, keyed by Rule.Condition, so that a "cleared" rule match is
+	// reported as a transition to False instead of the condition simply
+	// never being touched again.
+	conditions map[string]types.Condition
+}
+
+type compiledRule struct {
+	Rule
+	re     *regexp.Regexp
+	status types.ConditionStatus
+}
+
+// NewLogMonitor creates a new system log monitor from the config file at
+// configPath, returning an error instead of dying if the config cannot be
+// loaded, so that a single bad monitor configuration does not take down the
+// rest of node problem detector.
+func NewLogMonitor(configPath string) (types.Monitor, error) {
+	content, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %q: %v", configPath, err)
+	}
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration file %q: %v", configPath, err)
+	}
+	rules := make([]compiledRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern %q in %q: %v", rule.Pattern, configPath, err)
+		}
+		status := types.True
+		if rule.Status != "" {
+			status = types.ConditionStatus(rule.Status)
+		}
+		rules = append(rules, compiledRule{Rule: rule, re: re, status: status})
+	}
+	conditions := make(map[string]types.Condition)
+	now := time.Now()
+	for _, rule := range rules {
+		if rule.Type != "permanent" {
+			continue
+		}
+		if _, ok := conditions[rule.Condition]; ok {
+			continue
+		}
+		conditions[rule.Condition] = types.Condition{
+			Type:       rule.Condition,
+			Status:     types.False,
+			Transition: now,
+			Reason:     "NoProblem",
+			Message:    "No problem detected yet",
+		}
+	}
+	return &logMonitor{
+		configPath: configPath,
+		config:     config,
+		rules:      rules,
+		conditions: conditions,
+	}, nil
+}
+
+// NewLogMonitorOrDie creates a new system log monitor from the config file at
+// configPath, panics if the monitor cannot be created.
+func NewLogMonitorOrDie(configPath string) types.Monitor {
+	m, err := NewLogMonitor(configPath)
+	if err != nil {
+		glog.Fatalf("%v", err)
+	}
+	return m
+}
+
+// Start implements types.Monitor. It may be called again on the same
+// logMonitor after its returned channel closes, which restarts watching
+// from the current end of the log file; the supervisor in
+// pkg/problemdetector does this after a crash.
+func (l *logMonitor) Start(ctx context.Context) (<-chan *types.Status, error) {
+	glog.Infof("Start log monitor %q for %q", l.configPath, l.config.LogPath)
+	l.output = make(chan *types.Status)
+	go l.watchLoop(ctx)
+	return l.output, nil
+}
+
+// watchLoop tails the configured log file, translating new lines into
+// statuses as they match configured rules, until ctx is cancelled. A panic
+// here is recovered so that a single malformed log line cannot take down
+// the whole process; the supervisor in pkg/problemdetector notices the
+// output channel closing while ctx is still live and restarts the monitor.
+func (l *logMonitor) watchLoop(ctx context.Context) {
+	defer close(l.output)
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("Log monitor %q crashed: %v\n%s", l.config.Source, r, debug.Stack())
+		}
+	}()
+
+	f, err := os.Open(l.config.LogPath)
+	if err != nil {
+		glog.Errorf("Failed to open log %q: %v", l.config.LogPath, err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		glog.Errorf("Failed to seek to end of log %q: %v", l.config.LogPath, err)
+		return
+	}
+	reader := bufio.NewReader(f)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			time.Sleep(time.Second)
+			continue
+		}
+		metrics.RegisterLogLineScanned(l.config.Source)
+		if status := l.translate(line); status != nil {
+			select {
+			case l.output <- status:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (l *logMonitor) translate(line string) *types.Status {
+	for _, rule := range l.rules {
+		if !rule.re.MatchString(line) {
+			continue
+		}
+		event := types.Event{
+			Severity:  types.Warn,
+			Timestamp: time.Now(),
+			Reason:    rule.Reason,
+			Message:   line,
+		}
+		status := &types.Status{Source: l.config.Source, Events: []types.Event{event}}
+		if rule.Type == "permanent" {
+			status.Conditions = []types.Condition{l.updateCondition(rule.Condition, rule.status, rule.Reason, line)}
+		}
+		return status
+	}
+	return nil
+}
+
+// updateCondition records the current state of condition, updating
+// Transition only when the status actually changes, and returns the
+// condition to report. This lets a "cleared" rule (Status "False") report a
+// transition back to healthy instead of the condition simply going quiet
+// once the problem stops recurring.
+func (l *logMonitor) updateCondition(condition string, status types.ConditionStatus, reason, message string) types.Condition {
+	prev := l.conditions[condition]
+	updated := types.Condition{
+		Type:       condition,
+		Status:     status,
+		Transition: prev.Transition,
+		Reason:     reason,
+		Message:    message,
+	}
+	if prev.Status != status {
+		updated.Transition = time.Now()
+	}
+	l.conditions[condition] = updated
+	return updated
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"regexp"
+	"testing"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+func newTestLogMonitor(rules []compiledRule) *logMonitor {
+	conditions := make(map[string]types.Condition)
+	for _, rule := range rules {
+		if rule.Type != "permanent" {
+			continue
+		}
+		if _, ok := conditions[rule.Condition]; ok {
+			continue
+		}
+		conditions[rule.Condition] = types.Condition{Type: rule.Condition, Status: types.False}
+	}
+	return &logMonitor{
+		config:     Config{Source: "test"},
+		rules:      rules,
+		conditions: conditions,
+	}
+}
+
+func TestTranslatePermanentRuleSetsAndClearsCondition(t *testing.T) {
+	rules := []compiledRule{
+		{
+			Rule:   Rule{Type: "permanent", Condition: "KernelDeadlock", Reason: "OOMKilling", Pattern: "oom-kill"},
+			re:     regexp.MustCompile("oom-kill"),
+			status: types.True,
+		},
+		{
+			Rule:   Rule{Type: "permanent", Condition: "KernelDeadlock", Reason: "NoProblem", Pattern: "system is healthy", Status: "False"},
+			re:     regexp.MustCompile("system is healthy"),
+			status: types.False,
+		},
+	}
+	l := newTestLogMonitor(rules)
+
+	status := l.translate("oom-kill triggered")
+	if status == nil || len(status.Conditions) != 1 {
+		t.Fatalf("expected a condition to be reported, got %+v", status)
+	}
+	if status.Conditions[0].Status != types.True {
+		t.Fatalf("expected condition True after a match, got %v", status.Conditions[0].Status)
+	}
+	firstTransition := status.Conditions[0].Transition
+
+	status = l.translate("system is healthy again")
+	if status == nil || len(status.Conditions) != 1 {
+		t.Fatalf("expected the clearing rule to report a condition, got %+v", status)
+	}
+	if status.Conditions[0].Status != types.False {
+		t.Fatalf("expected condition to clear back to False, got %v", status.Conditions[0].Status)
+	}
+	if !status.Conditions[0].Transition.After(firstTransition) {
+		t.Fatalf("expected Transition to update when the status changes")
+	}
+}
+
+func TestTranslateNoMatchReturnsNil(t *testing.T) {
+	rules := []compiledRule{
+		{
+			Rule:   Rule{Type: "temporary", Reason: "Unrelated", Pattern: "does-not-match"},
+			re:     regexp.MustCompile("does-not-match"),
+			status: types.True,
+		},
+	}
+	l := newTestLogMonitor(rules)
+
+	if status := l.translate("nothing interesting here"); status != nil {
+		t.Fatalf("expected no status for a non-matching line, got %+v", status)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Status is the status other components should report to node problem detector.
+type Status struct {
+	// Source is the name of the source of the status.
+	Source string
+	// Events are the events generated by the corresponding monitor since last
+	// status report.
+	Events []Event
+	// Conditions are the current conditions observed by the corresponding
+	// monitor. Node problem detector will merge the conditions into the
+	// current node conditions.
+	Conditions []Condition
+}
+
+// Condition is the node condition used internally by problem detector.
+type Condition struct {
+	// Type is the condition type, it's the same as the real node condition type.
+	Type string
+	// Status is the condition status, it's the same as the real node condition status.
+	Status ConditionStatus
+	// Transition is the time when the condition transits to another status.
+	Transition time.Time
+	// Reason is a brief machine readable string explaining the status of the condition.
+	Reason string
+	// Message is a human readable string explaining the status of the condition.
+	Message string
+}
+
+// ConditionStatus is the status of the condition.
+type ConditionStatus string
+
+const (
+	True    ConditionStatus = "True"
+	False   ConditionStatus = "False"
+	Unknown ConditionStatus = "Unknown"
+)
+
+// Event is the event used internally by problem detector.
+type Event struct {
+	// Severity is the severity level of the event.
+	Severity Severity
+	// Timestamp is the time when the event is generated.
+	Timestamp time.Time
+	// Reason is a brief machine readable string explaining the event.
+	Reason string
+	// Message is a human readable string explaining the event.
+	Message string
+}
+
+// Severity is the severity level of the event.
+type Severity string
+
+const (
+	Info Severity = "info"
+	Warn Severity = "warn"
+)
+
+// Monitor monitors the node for a specific type of problem.
+type Monitor interface {
+	// Start starts the monitor. The monitor will start the internal sync routine and report
+	// status on the returned channel periodically, until ctx is cancelled. Once ctx is
+	// cancelled the monitor must close the returned channel and return.
+	Start(ctx context.Context) (<-chan *Status, error)
+}